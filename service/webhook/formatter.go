@@ -0,0 +1,248 @@
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"text/template"
+)
+
+// Event is the structured input passed to a Formatter: a subject/heading,
+// the message body, optional key/value fields, and an optional severity
+// (e.g. "critical", "warning").
+type Event struct {
+	Subject  string
+	Message  string
+	Fields   map[string]string
+	Severity string
+}
+
+// Formatter renders an Event into the wire payload a specific webhook
+// destination expects.
+type Formatter interface {
+	Format(event Event) ([]byte, error)
+}
+
+// SlackFormatter renders an Event as a Slack incoming-webhook payload.
+type SlackFormatter struct{}
+
+func (SlackFormatter) Format(event Event) ([]byte, error) {
+	text := event.Message
+	if event.Subject != "" {
+		text = fmt.Sprintf("*%s*\n%s", event.Subject, event.Message)
+	}
+
+	return json.Marshal(map[string]string{"text": text})
+}
+
+// DiscordFormatter renders an Event as a Discord webhook payload.
+type DiscordFormatter struct{}
+
+func (DiscordFormatter) Format(event Event) ([]byte, error) {
+	content := event.Message
+	if event.Subject != "" {
+		content = fmt.Sprintf("**%s**\n%s", event.Subject, event.Message)
+	}
+
+	return json.Marshal(map[string]string{"content": content})
+}
+
+// TeamsFormatter renders an Event as a Microsoft Teams MessageCard payload.
+type TeamsFormatter struct{}
+
+type teamsMessageCard struct {
+	Type     string         `json:"@type"`
+	Context  string         `json:"@context"`
+	Summary  string         `json:"summary,omitempty"`
+	Title    string         `json:"title,omitempty"`
+	Text     string         `json:"text,omitempty"`
+	Sections []teamsSection `json:"sections,omitempty"`
+}
+
+type teamsSection struct {
+	Facts []teamsFact `json:"facts,omitempty"`
+}
+
+type teamsFact struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+func (TeamsFormatter) Format(event Event) ([]byte, error) {
+	card := teamsMessageCard{
+		Type:    "MessageCard",
+		Context: "http://schema.org/extensions",
+		Summary: event.Subject,
+		Title:   event.Subject,
+		Text:    event.Message,
+	}
+
+	if len(event.Fields) > 0 {
+		card.Sections = []teamsSection{{Facts: sortedFacts(event.Fields)}}
+	}
+
+	return json.Marshal(card)
+}
+
+func sortedFacts(fields map[string]string) []teamsFact {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	facts := make([]teamsFact, 0, len(names))
+	for _, name := range names {
+		facts = append(facts, teamsFact{Name: name, Value: fields[name]})
+	}
+
+	return facts
+}
+
+// AlertmanagerFormatter renders an Event as a single-alert payload in the
+// shape Prometheus Alertmanager sends to its webhook receivers, so
+// Alertmanager-compatible receivers can be targeted directly.
+type AlertmanagerFormatter struct{}
+
+type alertmanagerPayload struct {
+	Version string              `json:"version"`
+	Status  string              `json:"status"`
+	Alerts  []alertmanagerAlert `json:"alerts"`
+}
+
+type alertmanagerAlert struct {
+	Status      string            `json:"status"`
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations"`
+}
+
+func (AlertmanagerFormatter) Format(event Event) ([]byte, error) {
+	status := "firing"
+	if event.Severity == "resolved" {
+		status = "resolved"
+	}
+
+	labels := map[string]string{"alertname": event.Subject}
+	for k, v := range event.Fields {
+		labels[k] = v
+	}
+
+	if event.Severity != "" {
+		labels["severity"] = event.Severity
+	}
+
+	payload := alertmanagerPayload{
+		Version: "4",
+		Status:  status,
+		Alerts: []alertmanagerAlert{
+			{
+				Status: status,
+				Labels: labels,
+				Annotations: map[string]string{
+					"summary":     event.Subject,
+					"description": event.Message,
+				},
+			},
+		},
+	}
+
+	return json.Marshal(payload)
+}
+
+// TemplateFormatter renders an Event through a user-supplied text/template,
+// so custom payload shapes can be defined in configuration without
+// recompiling. text/template performs no escaping of its own, so a
+// template that interpolates Subject or Message directly (e.g.
+// {{.Subject}}) into a JSON string risks an invalid or injectable payload
+// once that field contains a quote or newline, which ordinary alert text
+// often does. The template has a "json" function available for exactly
+// this: {{.Subject | json}} renders the value as a JSON string literal,
+// quotes and all, so it can be embedded directly without surrounding
+// quotes in the template text.
+type TemplateFormatter struct {
+	tmpl *template.Template
+}
+
+// NewTemplateFormatter parses tmplText as a text/template executed against
+// an Event, with a "json" function registered that renders its argument as
+// JSON (a string becomes a quoted JSON string literal) for safe embedding
+// in a JSON-shaped template.
+func NewTemplateFormatter(name, tmplText string) (*TemplateFormatter, error) {
+	tmpl, err := template.New(name).Funcs(template.FuncMap{
+		"json": func(v interface{}) (string, error) {
+			b, err := json.Marshal(v)
+			if err != nil {
+				return "", fmt.Errorf("webhook: marshaling template value to JSON: %w", err)
+			}
+
+			return string(b), nil
+		},
+	}).Parse(tmplText)
+	if err != nil {
+		return nil, fmt.Errorf("webhook: parsing template %q: %w", name, err)
+	}
+
+	return &TemplateFormatter{tmpl: tmpl}, nil
+}
+
+func (f *TemplateFormatter) Format(event Event) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := f.tmpl.Execute(&buf, event); err != nil {
+		return nil, fmt.Errorf("webhook: executing template: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+var formatterRegistry = struct {
+	mu    sync.RWMutex
+	items map[string]Formatter
+}{
+	items: map[string]Formatter{
+		"slack":        SlackFormatter{},
+		"discord":      DiscordFormatter{},
+		"teams":        TeamsFormatter{},
+		"alertmanager": AlertmanagerFormatter{},
+	},
+}
+
+// RegisterFormatter makes f available under name for later lookup via
+// FormatterByName. Registering under an existing name, including a
+// built-in one, replaces it.
+func RegisterFormatter(name string, f Formatter) {
+	formatterRegistry.mu.Lock()
+	defer formatterRegistry.mu.Unlock()
+
+	formatterRegistry.items[name] = f
+}
+
+// FormatterByName looks up a Formatter registered under name, built-in or
+// via RegisterFormatter.
+func FormatterByName(name string) (Formatter, bool) {
+	formatterRegistry.mu.RLock()
+	defer formatterRegistry.mu.RUnlock()
+
+	f, ok := formatterRegistry.items[name]
+
+	return f, ok
+}
+
+// WithFormatter configures subsequent Send calls to render the subject and
+// message through f before posting, and returns w so it can be chained
+// with New. Without a Formatter, Send posts message verbatim and subject
+// is ignored, matching the historical behaviour.
+func (w *Webhook) WithFormatter(f Formatter) *Webhook {
+	w.formatter = f
+
+	return w
+}
+
+// WithFormatter configures a Formatter for NewWithOptions.
+func WithFormatter(f Formatter) Option {
+	return func(w *Webhook) {
+		w.WithFormatter(f)
+	}
+}
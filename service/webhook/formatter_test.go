@@ -0,0 +1,146 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlackFormatter_Format(t *testing.T) {
+	t.Parallel()
+
+	body, err := SlackFormatter{}.Format(Event{Subject: "Alert", Message: "disk full"})
+	require.NoError(t, err)
+
+	var payload map[string]string
+	require.NoError(t, json.Unmarshal(body, &payload))
+	require.Equal(t, "*Alert*\ndisk full", payload["text"])
+}
+
+func TestDiscordFormatter_Format(t *testing.T) {
+	t.Parallel()
+
+	body, err := DiscordFormatter{}.Format(Event{Subject: "Alert", Message: "disk full"})
+	require.NoError(t, err)
+
+	var payload map[string]string
+	require.NoError(t, json.Unmarshal(body, &payload))
+	require.Equal(t, "**Alert**\ndisk full", payload["content"])
+}
+
+func TestTeamsFormatter_Format(t *testing.T) {
+	t.Parallel()
+
+	body, err := TeamsFormatter{}.Format(Event{
+		Subject: "Alert",
+		Message: "disk full",
+		Fields:  map[string]string{"host": "db-01", "disk": "/dev/sda1"},
+	})
+	require.NoError(t, err)
+
+	var payload teamsMessageCard
+	require.NoError(t, json.Unmarshal(body, &payload))
+	require.Equal(t, "MessageCard", payload.Type)
+	require.Equal(t, "Alert", payload.Title)
+	require.Equal(t, "disk full", payload.Text)
+	require.Len(t, payload.Sections, 1)
+	require.Equal(t, []teamsFact{
+		{Name: "disk", Value: "/dev/sda1"},
+		{Name: "host", Value: "db-01"},
+	}, payload.Sections[0].Facts)
+}
+
+func TestAlertmanagerFormatter_Format(t *testing.T) {
+	t.Parallel()
+
+	body, err := AlertmanagerFormatter{}.Format(Event{
+		Subject:  "DiskFull",
+		Message:  "disk is full",
+		Severity: "critical",
+		Fields:   map[string]string{"host": "db-01"},
+	})
+	require.NoError(t, err)
+
+	var payload alertmanagerPayload
+	require.NoError(t, json.Unmarshal(body, &payload))
+	require.Equal(t, "firing", payload.Status)
+	require.Len(t, payload.Alerts, 1)
+	require.Equal(t, "DiskFull", payload.Alerts[0].Labels["alertname"])
+	require.Equal(t, "critical", payload.Alerts[0].Labels["severity"])
+	require.Equal(t, "db-01", payload.Alerts[0].Labels["host"])
+	require.Equal(t, "disk is full", payload.Alerts[0].Annotations["description"])
+}
+
+func TestTemplateFormatter_Format(t *testing.T) {
+	t.Parallel()
+
+	f, err := NewTemplateFormatter("custom", `{"title":{{.Subject | json}},"body":{{.Message | json}}}`)
+	require.NoError(t, err)
+
+	body, err := f.Format(Event{Subject: "Alert", Message: "disk full"})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"title":"Alert","body":"disk full"}`, string(body))
+}
+
+func TestTemplateFormatter_FormatEscapesSpecialCharacters(t *testing.T) {
+	t.Parallel()
+
+	f, err := NewTemplateFormatter("custom", `{"title":{{.Subject | json}},"body":{{.Message | json}}}`)
+	require.NoError(t, err)
+
+	body, err := f.Format(Event{Subject: `He said "hi"`, Message: "line one\nline two"})
+	require.NoError(t, err)
+
+	var decoded map[string]string
+	require.NoError(t, json.Unmarshal(body, &decoded))
+	require.Equal(t, `He said "hi"`, decoded["title"])
+	require.Equal(t, "line one\nline two", decoded["body"])
+}
+
+func TestNewTemplateFormatter_InvalidTemplate(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewTemplateFormatter("broken", `{{.Subject`)
+
+	require.Error(t, err)
+}
+
+func TestFormatterRegistry(t *testing.T) {
+	t.Parallel()
+
+	f, ok := FormatterByName("slack")
+	require.True(t, ok)
+	require.IsType(t, SlackFormatter{}, f)
+
+	_, ok = FormatterByName("does-not-exist")
+	require.False(t, ok)
+
+	RegisterFormatter("my-custom", DiscordFormatter{})
+
+	f, ok = FormatterByName("my-custom")
+	require.True(t, ok)
+	require.IsType(t, DiscordFormatter{}, f)
+}
+
+func TestWebhook_SendUsesConfiguredFormatter(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"text":"*Disk Alert*\ndisk is full"}`, string(body))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	w := newAllowedWebhook(t, server.URL, 5).WithFormatter(SlackFormatter{})
+
+	err := w.Send(context.Background(), "Disk Alert", "disk is full")
+
+	require.NoError(t, err)
+}
@@ -0,0 +1,134 @@
+package webhook
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Observer instruments webhook dispatch. Send calls OnAttempt immediately
+// before sending each HTTP attempt; the function it returns is invoked
+// once that attempt completes, successfully or not. OnRetry is called
+// between attempts, just before Send sleeps ahead of the next one. The
+// zero value of NoopObserver implements Observer and is the default, so
+// callers who want neither OpenTelemetry nor Prometheus pay no cost.
+// Implementations typically adapt these hooks to a specific backend — see
+// the otelobserver and promobserver subpackages for OpenTelemetry spans
+// and Prometheus metrics respectively.
+type Observer interface {
+	// OnAttempt is called immediately before the HTTP request for a given
+	// 1-indexed attempt is sent. It returns the context Send should use
+	// for that attempt — an Observer backed by a tracer returns the
+	// context holding the span it started, carrying a TraceContext (see
+	// WithTraceContext) so the attempt's traceparent header correlates
+	// with that span instead of an unrelated one; an Observer with
+	// nothing to propagate can return ctx unchanged. statusCode passed to
+	// the returned function is 0 when the failure was a transport-level
+	// error rather than an HTTP response.
+	OnAttempt(ctx context.Context, attempt int) (context.Context, func(statusCode int, duration time.Duration, err error))
+	// OnRetry is called after a retryable failure, just before Send sleeps
+	// for wait ahead of the next attempt.
+	OnRetry(ctx context.Context, attempt int, wait time.Duration)
+}
+
+// NoopObserver is an Observer whose hooks do nothing. It is the default
+// Observer when none is configured via WithObserver.
+type NoopObserver struct{}
+
+func (NoopObserver) OnAttempt(ctx context.Context, _ int) (context.Context, func(int, time.Duration, error)) {
+	return ctx, func(int, time.Duration, error) {}
+}
+
+func (NoopObserver) OnRetry(context.Context, int, time.Duration) {}
+
+// WithObserver configures an Observer for subsequent Send calls and
+// returns w so it can be chained with New.
+func (w *Webhook) WithObserver(o Observer) *Webhook {
+	w.observer = o
+
+	return w
+}
+
+// WithObserver configures an Observer for NewWithOptions.
+func WithObserver(o Observer) Option {
+	return func(w *Webhook) {
+		w.WithObserver(o)
+	}
+}
+
+func (w Webhook) observerOrNoop() Observer {
+	if w.observer == nil {
+		return NoopObserver{}
+	}
+
+	return w.observer
+}
+
+// TraceContext carries the trace and span identifiers of a real,
+// already-started span so the traceparent header Send injects correlates
+// with it, rather than with an unrelated id generated independently. An
+// Observer backed by a tracer (see otelobserver) attaches one to the
+// context it returns from OnAttempt via WithTraceContext.
+type TraceContext struct {
+	// TraceID is the 32 lowercase-hex-character W3C trace id.
+	TraceID string
+	// SpanID is the 16 lowercase-hex-character W3C (parent-)span id.
+	SpanID string
+	// Sampled reports whether the span is sampled, encoded as the W3C
+	// trace-flags byte (01 if true, 00 otherwise).
+	Sampled bool
+}
+
+func (tc TraceContext) valid() bool {
+	return len(tc.TraceID) == 32 && len(tc.SpanID) == 16
+}
+
+type traceContextKey struct{}
+
+// WithTraceContext returns a copy of ctx carrying tc, so that a subsequent
+// Send call using that context injects a traceparent header derived from
+// tc instead of a freshly generated, unrelated trace id.
+func WithTraceContext(ctx context.Context, tc TraceContext) context.Context {
+	return context.WithValue(ctx, traceContextKey{}, tc)
+}
+
+func traceContextFromContext(ctx context.Context) (TraceContext, bool) {
+	tc, ok := ctx.Value(traceContextKey{}).(TraceContext)
+
+	return tc, ok
+}
+
+// injectTraceparent sets a W3C traceparent header on req. When ctx carries
+// a TraceContext (see WithTraceContext), the header is derived from it, so
+// it correlates with whatever span an Observer started for this attempt.
+// Otherwise a fresh, self-contained trace and span id is generated purely
+// so the header carries a valid traceparent shape for a receiver to log;
+// failure to generate random ids is non-fatal, the request is still sent,
+// just without the header.
+func injectTraceparent(ctx context.Context, req *http.Request) {
+	if tc, ok := traceContextFromContext(ctx); ok && tc.valid() {
+		flags := "00"
+		if tc.Sampled {
+			flags = "01"
+		}
+
+		req.Header.Set("traceparent", fmt.Sprintf("00-%s-%s-%s", tc.TraceID, tc.SpanID, flags))
+
+		return
+	}
+
+	var traceID [16]byte
+	if _, err := rand.Read(traceID[:]); err != nil {
+		return
+	}
+
+	var spanID [8]byte
+	if _, err := rand.Read(spanID[:]); err != nil {
+		return
+	}
+
+	req.Header.Set("traceparent", fmt.Sprintf("00-%s-%s-01", hex.EncodeToString(traceID[:]), hex.EncodeToString(spanID[:])))
+}
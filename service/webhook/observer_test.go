@@ -0,0 +1,85 @@
+package webhook
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type recordingObserver struct {
+	mu       sync.Mutex
+	attempts []int
+	results  []int
+	retries  []int
+}
+
+func (o *recordingObserver) OnAttempt(ctx context.Context, attempt int) (context.Context, func(int, time.Duration, error)) {
+	o.mu.Lock()
+	o.attempts = append(o.attempts, attempt)
+	o.mu.Unlock()
+
+	return ctx, func(statusCode int, _ time.Duration, _ error) {
+		o.mu.Lock()
+		o.results = append(o.results, statusCode)
+		o.mu.Unlock()
+	}
+}
+
+func (o *recordingObserver) OnRetry(_ context.Context, attempt int, _ time.Duration) {
+	o.mu.Lock()
+	o.retries = append(o.retries, attempt)
+	o.mu.Unlock()
+}
+
+func TestWebhook_SendNotifiesObserver(t *testing.T) {
+	t.Parallel()
+
+	var calls int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls++
+		if calls < 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	obs := &recordingObserver{}
+
+	w := newAllowedWebhook(t, server.URL, 5).
+		WithRetry(RetryConfig{MaxAttempts: 2, BaseBackoff: time.Millisecond, MaxBackoff: 10 * time.Millisecond}).
+		WithObserver(obs)
+
+	err := w.Send(context.Background(), "Test", `{"data":"test"}`)
+
+	require.NoError(t, err)
+	require.Equal(t, []int{1, 2}, obs.attempts)
+	require.Equal(t, []int{http.StatusServiceUnavailable, http.StatusOK}, obs.results)
+	require.Equal(t, []int{1}, obs.retries)
+}
+
+func TestWebhook_SendInjectsTraceparentHeader(t *testing.T) {
+	t.Parallel()
+
+	var traceparent string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	w := newAllowedWebhook(t, server.URL, 5)
+
+	err := w.Send(context.Background(), "Test", `{"data":"test"}`)
+
+	require.NoError(t, err)
+	require.Regexp(t, `^00-[0-9a-f]{32}-[0-9a-f]{16}-01$`, traceparent)
+}
@@ -0,0 +1,83 @@
+// Package otelobserver adapts webhook.Observer to OpenTelemetry tracing.
+package otelobserver
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/deny-7/notify-fork/service/webhook"
+)
+
+// instrumentationName identifies this package to the OpenTelemetry SDK
+// when no explicit Tracer is supplied to New.
+const instrumentationName = "github.com/deny-7/notify-fork/service/webhook"
+
+// Observer records one OpenTelemetry span per HTTP attempt, with
+// http.method, http.url, http.status_code, webhook.attempt and
+// webhook.duration_ms attributes, and derives the span status from the
+// HTTP outcome. When the span has a valid span context, its trace and span
+// id are also propagated onto the outgoing request's traceparent header, so
+// a receiver's own tracing correlates with this span rather than with an
+// unrelated generated id.
+type Observer struct {
+	tracer trace.Tracer
+	method string
+	url    string
+}
+
+// New returns an Observer that records spans for requests to url using
+// method (typically http.MethodPost). If tracer is nil, the global
+// OpenTelemetry tracer provider is used.
+func New(tracer trace.Tracer, method, url string) *Observer {
+	if tracer == nil {
+		tracer = otel.Tracer(instrumentationName)
+	}
+
+	return &Observer{tracer: tracer, method: method, url: url}
+}
+
+func (o *Observer) OnAttempt(ctx context.Context, attempt int) (context.Context, func(statusCode int, duration time.Duration, err error)) {
+	spanCtx, span := o.tracer.Start(ctx, "webhook.send", trace.WithAttributes(
+		attribute.String("http.method", o.method),
+		attribute.String("http.url", o.url),
+		attribute.Int("webhook.attempt", attempt),
+	))
+
+	if sc := span.SpanContext(); sc.IsValid() {
+		spanCtx = webhook.WithTraceContext(spanCtx, webhook.TraceContext{
+			TraceID: sc.TraceID().String(),
+			SpanID:  sc.SpanID().String(),
+			Sampled: sc.IsSampled(),
+		})
+	}
+
+	return spanCtx, func(statusCode int, duration time.Duration, err error) {
+		span.SetAttributes(
+			attribute.Int("http.status_code", statusCode),
+			attribute.Int64("webhook.duration_ms", duration.Milliseconds()),
+		)
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		} else {
+			span.SetStatus(codes.Ok, "")
+		}
+
+		span.End()
+	}
+}
+
+func (o *Observer) OnRetry(ctx context.Context, attempt int, wait time.Duration) {
+	trace.SpanFromContext(ctx).AddEvent("webhook.retry", trace.WithAttributes(
+		attribute.Int("webhook.attempt", attempt),
+		attribute.Int64("webhook.wait_ms", wait.Milliseconds()),
+	))
+}
+
+var _ webhook.Observer = (*Observer)(nil)
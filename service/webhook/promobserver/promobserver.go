@@ -0,0 +1,66 @@
+// Package promobserver adapts webhook.Observer to Prometheus metrics.
+package promobserver
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/deny-7/notify-fork/service/webhook"
+)
+
+// Observer records webhook dispatch outcomes as Prometheus metrics:
+// webhook_requests_total{status}, webhook_request_duration_seconds and
+// webhook_retries_total.
+type Observer struct {
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+	retries  prometheus.Counter
+}
+
+// New registers the Observer's metrics with reg and returns the Observer.
+// If reg is nil, prometheus.DefaultRegisterer is used.
+func New(reg prometheus.Registerer) *Observer {
+	if reg == nil {
+		reg = prometheus.DefaultRegisterer
+	}
+
+	o := &Observer{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "webhook_requests_total",
+			Help: "Total number of webhook HTTP attempts, labeled by outcome status.",
+		}, []string{"status"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name: "webhook_request_duration_seconds",
+			Help: "Webhook HTTP attempt duration in seconds.",
+		}, []string{"status"}),
+		retries: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "webhook_retries_total",
+			Help: "Total number of webhook delivery retries.",
+		}),
+	}
+
+	reg.MustRegister(o.requests, o.duration, o.retries)
+
+	return o
+}
+
+func (o *Observer) OnAttempt(ctx context.Context, _ int) (context.Context, func(statusCode int, duration time.Duration, err error)) {
+	return ctx, func(statusCode int, duration time.Duration, err error) {
+		status := "error"
+		if statusCode != 0 {
+			status = strconv.Itoa(statusCode)
+		}
+
+		o.requests.WithLabelValues(status).Inc()
+		o.duration.WithLabelValues(status).Observe(duration.Seconds())
+	}
+}
+
+func (o *Observer) OnRetry(context.Context, int, time.Duration) {
+	o.retries.Inc()
+}
+
+var _ webhook.Observer = (*Observer)(nil)
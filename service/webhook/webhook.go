@@ -2,57 +2,810 @@ package webhook
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
 	"fmt"
+	"hash"
+	"io"
+	"math/rand"
+	"net"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
+	"sync"
+	"syscall"
 	"time"
 )
 
+// Default retry parameters used when a Webhook is created without explicit
+// retry configuration. A MaxAttempts of 1 disables retries, preserving the
+// historical behaviour of Send.
+const (
+	DefaultMaxAttempts = 1
+	DefaultBaseBackoff = 500 * time.Millisecond
+	DefaultMaxBackoff  = 30 * time.Second
+)
+
+// RetryConfig controls how Send retries transient failures: network errors,
+// 408, 429 and 5xx responses.
+type RetryConfig struct {
+	// MaxAttempts is the total number of attempts, including the first.
+	// Values below 1 are treated as 1, which disables retrying.
+	MaxAttempts int
+	// BaseBackoff is the base delay used for exponential backoff between
+	// attempts: min(MaxBackoff, BaseBackoff*2^(n-1)) plus uniform jitter
+	// in [0, BaseBackoff).
+	BaseBackoff time.Duration
+	// MaxBackoff caps the computed backoff delay. Zero means no cap.
+	MaxBackoff time.Duration
+	// AttemptTimeout bounds a single attempt independently of the overall
+	// context deadline. Zero means no per-attempt timeout.
+	AttemptTimeout time.Duration
+}
+
 // Webhook struct holds necessary data to communicate with the Webhook API.
 type Webhook struct {
-	webhook string
-	timeout int
+	webhook   string
+	timeout   int
+	retry     RetryConfig
+	async     *asyncManager
+	sign      *SignConfig
+	hosts     *hostPolicy
+	formatter Formatter
+	observer  Observer
 }
 
-// New returns a new instance of a webhook service.
-func New(webhook string, timeout int) *Webhook {
+// Option configures a Webhook constructed via NewWithOptions.
+type Option func(*Webhook)
+
+// NewWithOptions returns a new instance of a webhook service configured via
+// functional options, so timeout, retries, async dispatch, signing, host
+// policy, formatter and observer settings can be composed freely. There is
+// no option for custom request headers or TLS configuration.
+func NewWithOptions(webhook string, opts ...Option) *Webhook {
 	w := &Webhook{
 		webhook: webhook,
-		timeout: timeout,
+		retry: RetryConfig{
+			MaxAttempts: DefaultMaxAttempts,
+			BaseBackoff: DefaultBaseBackoff,
+			MaxBackoff:  DefaultMaxBackoff,
+		},
+		async: newAsyncManager(),
+	}
+
+	for _, opt := range opts {
+		opt(w)
+	}
+
+	return w
+}
+
+// New returns a new instance of a webhook service. It is kept as a
+// compatibility shim over NewWithOptions for callers using the original
+// constructor signature; new code should prefer NewWithOptions.
+func New(webhook string, timeout int) *Webhook {
+	return NewWithOptions(webhook, WithTimeoutSeconds(timeout))
+}
+
+// WithTimeoutSeconds sets the overall HTTP client timeout, in seconds,
+// matching the historical timeout constructor parameter.
+func WithTimeoutSeconds(timeout int) Option {
+	return func(w *Webhook) {
+		w.timeout = timeout
+	}
+}
+
+// WithRetryConfig configures retry behaviour for NewWithOptions.
+func WithRetryConfig(cfg RetryConfig) Option {
+	return func(w *Webhook) {
+		w.WithRetry(cfg)
+	}
+}
+
+// WithAsyncConfig configures fire-and-forget dispatch behaviour for
+// NewWithOptions.
+func WithAsyncConfig(cfg AsyncConfig) Option {
+	return func(w *Webhook) {
+		w.WithAsync(cfg)
+	}
+}
+
+// WithSigning configures HMAC request signing for NewWithOptions.
+func WithSigning(cfg SignConfig) Option {
+	return func(w *Webhook) {
+		w.WithSigning(cfg)
+	}
+}
+
+// WithAllowedHosts configures NewWithOptions to permit connections to the
+// given hostnames or IP literals even if they fall within a denied range.
+func WithAllowedHosts(hosts []string) Option {
+	return func(w *Webhook) {
+		w.WithAllowedHosts(hosts)
+	}
+}
+
+// WithDeniedCIDRs adds CIDR ranges NewWithOptions rejects beyond the
+// built-in loopback/link-local/private/CGNAT defaults.
+func WithDeniedCIDRs(cidrs []string) Option {
+	return func(w *Webhook) {
+		w.WithDeniedCIDRs(cidrs)
+	}
+}
+
+// WithProxy routes NewWithOptions' requests through the given proxy URL
+// instead of dialing the webhook host directly.
+func WithProxy(proxyURL string) Option {
+	return func(w *Webhook) {
+		w.WithProxy(proxyURL)
+	}
+}
+
+// WithRetry configures retry behaviour for subsequent Send calls and
+// returns w so it can be chained with New.
+func (w *Webhook) WithRetry(cfg RetryConfig) *Webhook {
+	if cfg.MaxAttempts < 1 {
+		cfg.MaxAttempts = 1
+	}
+
+	w.retry = cfg
+
+	return w
+}
+
+// AsyncConfig controls fire-and-forget dispatch via AsyncSend.
+type AsyncConfig struct {
+	// Timeout bounds a detached delivery independently of the caller's
+	// context, which is no longer consulted for cancellation once
+	// AsyncSend has dispatched. Zero means no timeout.
+	Timeout time.Duration
+	// MaxConcurrent caps the number of deliveries AsyncSend runs at once.
+	// Zero means unbounded: every call spawns its own goroutine.
+	MaxConcurrent int
+}
+
+// asyncJob is a unit of work handed to the bounded worker pool.
+type asyncJob struct {
+	run func()
+}
+
+// asyncManager owns the mutable state behind AsyncSend's bounded worker
+// pool: the configured AsyncConfig and the channel/stop-signal pair its
+// workers use, if any. Webhook holds a pointer to one rather than the
+// fields directly, for two reasons. First, several Webhook methods (Send,
+// client, doAttempt) use a value receiver, so every call copies the
+// Webhook; a pointer lets those copies keep sharing one pool instead of
+// each spawning its own. Second, it lets WithAsync reconfigure the pool
+// while AsyncSend calls are in flight without racing them: every access to
+// the mutable fields goes through asyncManager's own mutex.
+type asyncManager struct {
+	mu   sync.Mutex
+	cfg  AsyncConfig
+	jobs chan asyncJob
+	stop chan struct{}
+}
+
+func newAsyncManager() *asyncManager {
+	return &asyncManager{}
+}
+
+// configure installs cfg, starting cfg.MaxConcurrent long-lived workers
+// when it's positive. The previous pool's workers, if any, are told to
+// stop; any job still sitting in their queue is abandoned rather than
+// risking a send on a channel nothing is reading from anymore.
+func (m *asyncManager) configure(cfg AsyncConfig) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.stop != nil {
+		close(m.stop)
+	}
+
+	m.cfg = cfg
+	m.jobs = nil
+	m.stop = nil
+
+	if cfg.MaxConcurrent > 0 {
+		jobs := make(chan asyncJob, cfg.MaxConcurrent)
+		stop := make(chan struct{})
+
+		for i := 0; i < cfg.MaxConcurrent; i++ {
+			go runAsyncWorker(jobs, stop)
+		}
+
+		m.jobs = jobs
+		m.stop = stop
+	}
+}
+
+// snapshot returns the timeout, job queue and stop signal AsyncSend needs
+// to dispatch under the current configuration. jobs and stop are nil when
+// no bounded pool is configured. stop lets AsyncSend notice a reconfigure
+// that happens after it has read jobs but before it finishes sending to
+// it, so it doesn't block forever on a queue nothing is draining anymore.
+func (m *asyncManager) snapshot() (time.Duration, chan asyncJob, chan struct{}) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.cfg.Timeout, m.jobs, m.stop
+}
+
+// runAsyncWorker drains jobs until stop is closed. jobs and stop are
+// captured at worker-start time rather than read from the shared
+// asyncManager on every iteration, so a worker keeps serving the pool it
+// was started for even after WithAsync installs a new one.
+func runAsyncWorker(jobs chan asyncJob, stop chan struct{}) {
+	for {
+		select {
+		case <-stop:
+			return
+		case job := <-jobs:
+			job.run()
+		}
+	}
+}
+
+// WithAsync configures fire-and-forget dispatch behaviour for subsequent
+// AsyncSend calls and returns w so it can be chained with New. When
+// cfg.MaxConcurrent is positive, a pool of that many long-lived workers
+// bounds concurrent deliveries; once the pool's queue is full, AsyncSend
+// blocks the calling goroutine until a slot frees rather than spawning a
+// goroutine to wait, so sustained overload applies backpressure to callers
+// instead of leaking goroutines. Reconfiguring while sends are outstanding
+// is safe.
+func (w *Webhook) WithAsync(cfg AsyncConfig) *Webhook {
+	w.async.configure(cfg)
+
+	return w
+}
+
+// detachedContext carries the values of a parent context (e.g. trace/span
+// IDs used for logging) without inheriting its cancellation or deadline.
+// It mirrors context.WithoutCancel for the minimum surface AsyncSend needs.
+type detachedContext struct {
+	parent context.Context
+}
+
+func withoutCancel(parent context.Context) context.Context {
+	return detachedContext{parent: parent}
+}
+
+func (detachedContext) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (detachedContext) Done() <-chan struct{}       { return nil }
+func (detachedContext) Err() error                  { return nil }
+
+func (d detachedContext) Value(key interface{}) interface{} {
+	return d.parent.Value(key)
+}
+
+// AsyncSend dispatches Send using a context detached from ctx, so
+// cancellation of the caller's context (e.g. once an inbound HTTP request
+// has been served) does not abort in-flight delivery. This mirrors the fix
+// in Ory Kratos where response.ignore=true webhooks were spuriously
+// canceled once the originating request completed. Values carried by ctx,
+// such as trace/span IDs used for logging, are preserved. If WithAsync
+// configured MaxConcurrent, dispatch is handed to that bounded worker pool,
+// which may block the calling goroutine while the pool's queue is full;
+// otherwise this call spawns its own goroutine and returns immediately. A
+// WithAsync call that reconfigures the pool while AsyncSend is blocked
+// handing off a job falls back to running that one dispatch in its own
+// goroutine, rather than leaving AsyncSend blocked on a queue the old
+// pool's workers have stopped draining. The returned channel receives the
+// single delivery result and is then closed.
+func (w *Webhook) AsyncSend(ctx context.Context, subject string, message string) <-chan error {
+	done := make(chan error, 1)
+	detached := withoutCancel(ctx)
+
+	timeout, jobs, stop := w.async.snapshot()
+
+	var cancel context.CancelFunc
+	if timeout > 0 {
+		detached, cancel = context.WithTimeout(detached, timeout)
+	}
+
+	dispatch := func() {
+		if cancel != nil {
+			defer cancel()
+		}
+
+		done <- w.Send(detached, subject, message)
+		close(done)
+	}
+
+	if jobs != nil {
+		select {
+		case jobs <- asyncJob{run: dispatch}:
+		case <-stop:
+			go dispatch()
+		}
+	} else {
+		go dispatch()
+	}
+
+	return done
+}
+
+// SignAlgorithm identifies a supported HMAC hash algorithm for request
+// signing.
+type SignAlgorithm int
+
+const (
+	// SignSHA256 signs the payload with HMAC-SHA256. This is the default.
+	SignSHA256 SignAlgorithm = iota
+	// SignSHA512 signs the payload with HMAC-SHA512.
+	SignSHA512
+)
+
+func (a SignAlgorithm) hasher() func() hash.Hash {
+	if a == SignSHA512 {
+		return sha512.New
+	}
+
+	return sha256.New
+}
+
+func (a SignAlgorithm) String() string {
+	if a == SignSHA512 {
+		return "sha512"
+	}
+
+	return "sha256"
+}
+
+// DefaultSignatureHeader is the header used to carry the HMAC signature
+// when SignConfig.HeaderName is left empty.
+const DefaultSignatureHeader = "X-Webhook-Signature"
+
+// SignConfig controls HMAC signing of outgoing webhook payloads.
+type SignConfig struct {
+	// Secret is the shared key used to compute the HMAC. Required.
+	Secret string
+	// Algorithm selects the HMAC hash function. Defaults to SignSHA256.
+	Algorithm SignAlgorithm
+	// HeaderName is the header the signature is sent in. Defaults to
+	// DefaultSignatureHeader.
+	HeaderName string
+	// TimestampHeader, when set, adds a header carrying the current Unix
+	// timestamp and signs "v0:{timestamp}:{body}" instead of the raw body
+	// (Slack-style), to defend against replay of a captured signature.
+	TimestampHeader string
+}
+
+// WithSigning configures HMAC request signing for subsequent Send calls
+// and returns w so it can be chained with New.
+func (w *Webhook) WithSigning(cfg SignConfig) *Webhook {
+	if cfg.HeaderName == "" {
+		cfg.HeaderName = DefaultSignatureHeader
+	}
+
+	w.sign = &cfg
+
+	return w
+}
+
+// sign computes the HMAC signature header value for body, setting the
+// timestamp header first if one is configured.
+func (cfg SignConfig) sign(req *http.Request, body string) {
+	signed := body
+
+	if cfg.TimestampHeader != "" {
+		ts := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set(cfg.TimestampHeader, ts)
+		signed = fmt.Sprintf("v0:%s:%s", ts, body)
+	}
+
+	mac := hmac.New(cfg.Algorithm.hasher(), []byte(cfg.Secret))
+	mac.Write([]byte(signed))
+
+	req.Header.Set(cfg.HeaderName, fmt.Sprintf("%s=%s", cfg.Algorithm, hex.EncodeToString(mac.Sum(nil))))
+}
+
+// defaultDeniedCIDRs are rejected even when no DeniedCIDRs are configured,
+// to guard against SSRF via attacker-controlled webhook URLs: loopback,
+// link-local (which covers the 169.254.169.254 cloud metadata address),
+// RFC 1918 private ranges, CGNAT, and IPv6 unique-local addresses.
+var defaultDeniedCIDRs = mustParseCIDRs([]string{
+	"127.0.0.0/8",
+	"::1/128",
+	"169.254.0.0/16",
+	"fe80::/10",
+	"10.0.0.0/8",
+	"172.16.0.0/12",
+	"192.168.0.0/16",
+	"100.64.0.0/10",
+	"fc00::/7",
+})
+
+func mustParseCIDRs(cidrs []string) []*net.IPNet {
+	nets := make([]*net.IPNet, 0, len(cidrs))
+
+	for _, cidr := range cidrs {
+		_, n, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(fmt.Sprintf("webhook: invalid CIDR %q: %v", cidr, err))
+		}
+
+		nets = append(nets, n)
+	}
+
+	return nets
+}
+
+// hostPolicy guards outgoing connections against SSRF: Send always refuses
+// to connect to loopback, link-local, private, CGNAT or explicitly denied
+// ranges unless the target host or resolved IP is explicitly allowed.
+// WithAllowedHosts, WithDeniedCIDRs and WithProxy configure an instance of
+// this guard, but none of them are required to engage it.
+type hostPolicy struct {
+	allowed map[string]struct{}
+	denied  []*net.IPNet
+	proxy   string
+}
+
+func newHostPolicy() *hostPolicy {
+	return &hostPolicy{
+		allowed: map[string]struct{}{},
+		denied:  append([]*net.IPNet(nil), defaultDeniedCIDRs...),
+	}
+}
+
+func (p *hostPolicy) checkDenied(ip net.IP) error {
+	for _, denied := range p.denied {
+		if denied.Contains(ip) {
+			return fmt.Errorf("webhook: connections to %s are not allowed (matches denied range %s)", ip, denied)
+		}
+	}
+
+	return nil
+}
+
+// transport builds an *http.Transport whose DialContext resolves the host
+// and, via the underlying net.Dialer.Control hook, inspects the resolved
+// IP after DNS but before connecting. Because the check runs per dial
+// attempt, it re-applies on every redirect and is immune to DNS
+// rebinding between the check and the connect call.
+func (p *hostPolicy) transport() (*http.Transport, error) {
+	transport := &http.Transport{
+		// A fresh transport is built for every attempt, so an idle
+		// keep-alive connection would never be reused and would only hold
+		// its goroutines open.
+		DisableKeepAlives: true,
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			host, _, err := net.SplitHostPort(addr)
+			if err != nil {
+				return nil, err
+			}
+
+			dialer := &net.Dialer{
+				Control: func(_, resolvedAddr string, _ syscall.RawConn) error {
+					if _, ok := p.allowed[host]; ok {
+						return nil
+					}
+
+					resolvedHost, _, err := net.SplitHostPort(resolvedAddr)
+					if err != nil {
+						return err
+					}
+
+					ip := net.ParseIP(resolvedHost)
+					if ip == nil {
+						return fmt.Errorf("webhook: could not parse resolved address %q", resolvedAddr)
+					}
+
+					if _, ok := p.allowed[ip.String()]; ok {
+						return nil
+					}
+
+					return p.checkDenied(ip)
+				},
+			}
+
+			return dialer.DialContext(ctx, network, addr)
+		},
+	}
+
+	if p.proxy != "" {
+		proxyURL, err := url.Parse(p.proxy)
+		if err != nil {
+			return nil, fmt.Errorf("webhook: invalid proxy URL %q: %w", p.proxy, err)
+		}
+
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	return transport, nil
+}
+
+func (w *Webhook) ensureHostPolicy() *hostPolicy {
+	if w.hosts == nil {
+		w.hosts = newHostPolicy()
+	}
+
+	return w.hosts
+}
+
+// WithAllowedHosts permits subsequent Send calls to connect to the given
+// hostnames or IP literals even if they fall within a denied range, and
+// returns w so it can be chained with New.
+func (w *Webhook) WithAllowedHosts(hosts []string) *Webhook {
+	p := w.ensureHostPolicy()
+
+	for _, host := range hosts {
+		p.allowed[host] = struct{}{}
 	}
 
 	return w
 }
 
-// Send takes a JSON message and sends it to specified webook URL, subject is ignored.
-func (w Webhook) Send(ctx context.Context, _ string, message string) error {
-	jsonReader := strings.NewReader(message)
+// WithDeniedCIDRs adds CIDR ranges for subsequent Send calls to reject,
+// beyond the built-in loopback/link-local/private/CGNAT defaults, and
+// returns w so it can be chained with New.
+func (w *Webhook) WithDeniedCIDRs(cidrs []string) *Webhook {
+	p := w.ensureHostPolicy()
+	p.denied = append(p.denied, mustParseCIDRs(cidrs)...)
+
+	return w
+}
+
+// WithProxy routes subsequent Send calls through the given proxy URL
+// instead of dialing the webhook host directly, and returns w so it can
+// be chained with New.
+func (w *Webhook) WithProxy(proxyURL string) *Webhook {
+	p := w.ensureHostPolicy()
+	p.proxy = proxyURL
+
+	return w
+}
+
+// AttemptError is returned by Send when delivery fails, either because
+// every attempt has been exhausted or because a failure was not retryable.
+// It wraps the last HTTP status and body seen (if any) along with the
+// number of attempts made, so callers can log or alert on persistent
+// failures.
+type AttemptError struct {
+	Attempts   int
+	StatusCode int
+	Status     string
+	Body       string
+	Err        error
+}
+
+func (e *AttemptError) Error() string {
+	if e.StatusCode == 0 {
+		return fmt.Sprintf("webhook failed after %d attempt(s): %s", e.Attempts, e.Err)
+	}
+
+	if e.Attempts <= 1 {
+		return fmt.Sprintf("webhook returned status %s", e.Status)
+	}
+
+	return fmt.Sprintf("webhook returned status %s after %d attempts", e.Status, e.Attempts)
+}
+
+func (e *AttemptError) Unwrap() error {
+	return e.Err
+}
+
+// attemptResult captures the outcome of a single Send attempt.
+type attemptResult struct {
+	statusCode int
+	status     string
+	body       string
+	retryAfter time.Duration
+	transport  bool // true if the failure happened before an HTTP response was received
+	err        error
+}
+
+func (r attemptResult) retryable() bool {
+	return r.transport ||
+		r.statusCode == http.StatusRequestTimeout ||
+		r.statusCode == http.StatusTooManyRequests ||
+		r.statusCode >= 500
+}
+
+// Send sends message to the specified webhook URL. Without a Formatter
+// configured via WithFormatter, message is posted verbatim and subject is
+// ignored, matching the historical behaviour; otherwise subject and
+// message are rendered into the destination's wire format first. Transient
+// failures (network errors, 408, 429 and 5xx responses) are retried
+// according to the configured RetryConfig: attempts are spaced by an
+// exponential backoff with jitter, honoring any Retry-After header the
+// server sends. The loop aborts immediately if ctx is cancelled, whether
+// between or during attempts.
+func (w Webhook) Send(ctx context.Context, subject string, message string) error {
+	payload := message
+
+	if w.formatter != nil {
+		formatted, err := w.formatter.Format(Event{Subject: subject, Message: message})
+		if err != nil {
+			return err
+		}
+
+		payload = string(formatted)
+	}
+
+	retry := w.retry
+	if retry.MaxAttempts < 1 {
+		retry.MaxAttempts = 1
+	}
+
+	obs := w.observerOrNoop()
+
+	var last attemptResult
+	var attemptsMade int
+
+	for attempt := 1; attempt <= retry.MaxAttempts; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		attemptsMade = attempt
+		start := time.Now()
+		attemptCtx, done := obs.OnAttempt(ctx, attempt)
+		last = w.doAttempt(attemptCtx, retry.AttemptTimeout, payload)
+		done(last.statusCode, time.Since(start), last.err)
+
+		if last.err == nil {
+			return nil
+		}
+
+		if attempt == retry.MaxAttempts || !last.retryable() {
+			break
+		}
+
+		wait := backoffDelay(retry, attempt, last.retryAfter)
+		obs.OnRetry(ctx, attempt, wait)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+
+	return &AttemptError{
+		Attempts:   attemptsMade,
+		StatusCode: last.statusCode,
+		Status:     last.status,
+		Body:       last.body,
+		Err:        last.err,
+	}
+}
+
+// client builds the *http.Client used for a single attempt. The host
+// policy guard always applies, even when Webhook was built without any of
+// WithAllowedHosts, WithDeniedCIDRs or WithProxy: it falls back to a
+// default-denying hostPolicy so loopback, link-local, private, CGNAT and
+// IPv6 unique-local destinations are rejected regardless of configuration.
+// Those With* options only add to or allowlist against the guard; there is
+// no way to turn it off short of WithAllowedHosts naming the target.
+func (w Webhook) client() (*http.Client, error) {
+	client := &http.Client{
+		Timeout: time.Duration(w.timeout) * time.Second,
+	}
+
+	hosts := w.hosts
+	if hosts == nil {
+		hosts = newHostPolicy()
+	}
+
+	transport, err := hosts.transport()
+	if err != nil {
+		return nil, err
+	}
+
+	client.Transport = transport
+
+	return client, nil
+}
+
+// doAttempt performs a single POST of message and reports the outcome.
+func (w Webhook) doAttempt(ctx context.Context, attemptTimeout time.Duration, message string) attemptResult {
+	if attemptTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, attemptTimeout)
+		defer cancel()
+	}
 
 	req, err := http.NewRequestWithContext(
 		ctx,
 		http.MethodPost,
 		w.webhook,
-		jsonReader,
+		strings.NewReader(message),
 	)
 	if err != nil {
-		return err
+		return attemptResult{err: err}
 	}
 
 	req.Header.Set("Content-Type", "application/json")
+	injectTraceparent(ctx, req)
 
-	client := &http.Client{
-		Timeout: time.Duration(w.timeout) * time.Second,
+	if w.sign != nil {
+		w.sign.sign(req, message)
+	}
+
+	client, err := w.client()
+	if err != nil {
+		return attemptResult{err: err}
 	}
 
 	resp, err := client.Do(req)
 	if err != nil {
-		return err
+		return attemptResult{transport: true, err: err}
 	}
 	defer resp.Body.Close()
 
+	body, _ := io.ReadAll(resp.Body)
+
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return fmt.Errorf("webhook returned status %s", resp.Status)
+		retryAfter, _ := parseRetryAfter(resp.Header.Get("Retry-After"))
+
+		return attemptResult{
+			statusCode: resp.StatusCode,
+			status:     resp.Status,
+			body:       string(body),
+			retryAfter: retryAfter,
+			err:        fmt.Errorf("webhook returned status %s", resp.Status),
+		}
 	}
 
-	return nil
+	return attemptResult{statusCode: resp.StatusCode, status: resp.Status}
+}
+
+// parseRetryAfter parses a Retry-After header in either the seconds or the
+// HTTP-date form, returning the remaining duration to wait.
+func parseRetryAfter(header string) (time.Duration, bool) {
+	if header == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(header); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if when, err := http.ParseTime(header); err == nil {
+		if d := time.Until(when); d > 0 {
+			return d, true
+		}
+
+		return 0, true
+	}
+
+	return 0, false
+}
+
+// backoffDelay computes the exponential backoff with jitter for the given
+// 1-indexed attempt number, deferring to retryAfter when the server
+// provided one.
+func backoffDelay(cfg RetryConfig, attempt int, retryAfter time.Duration) time.Duration {
+	if retryAfter > 0 {
+		return retryAfter
+	}
+
+	backoff := cfg.BaseBackoff
+	for i := 1; i < attempt; i++ {
+		backoff *= 2
+		if cfg.MaxBackoff > 0 && backoff > cfg.MaxBackoff {
+			backoff = cfg.MaxBackoff
+			break
+		}
+	}
+
+	if cfg.MaxBackoff > 0 && backoff > cfg.MaxBackoff {
+		backoff = cfg.MaxBackoff
+	}
+
+	var jitter time.Duration
+	if cfg.BaseBackoff > 0 {
+		jitter = time.Duration(rand.Int63n(int64(cfg.BaseBackoff)))
+	}
+
+	return backoff + jitter
 }
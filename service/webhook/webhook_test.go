@@ -2,17 +2,38 @@ package webhook
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"runtime"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/require"
 )
 
+// newAllowedWebhook returns a Webhook targeting serverURL with its host
+// allowlisted against the SSRF guard. The guard denies loopback by
+// default, and httptest servers bind to it, so tests that expect Send to
+// actually reach one need to opt that host back in explicitly.
+func newAllowedWebhook(t *testing.T, serverURL string, timeoutSeconds int) *Webhook {
+	t.Helper()
+
+	u, err := url.Parse(serverURL)
+	require.NoError(t, err)
+
+	return New(serverURL, timeoutSeconds).WithAllowedHosts([]string{u.Hostname()})
+}
+
 func TestNew(t *testing.T) {
 	t.Parallel()
 
@@ -153,7 +174,7 @@ func TestWebhook_Send(t *testing.T) {
 			defer server.Close()
 
 			// Create webhook with server URL
-			w := New(server.URL, tt.timeout)
+			w := newAllowedWebhook(t, server.URL, tt.timeout)
 
 			err := w.Send(context.Background(), tt.subject, tt.message)
 
@@ -176,7 +197,7 @@ func TestWebhook_SendWithContextCancellation(t *testing.T) {
 	}))
 	defer server.Close()
 
-	w := New(server.URL, 5)
+	w := newAllowedWebhook(t, server.URL, 5)
 
 	// Create a context that is already cancelled
 	ctx, cancel := context.WithCancel(context.Background())
@@ -198,7 +219,7 @@ func TestWebhook_SendWithTimeout(t *testing.T) {
 	}))
 	defer server.Close()
 
-	w := New(server.URL, 1) // 1 second timeout
+	w := newAllowedWebhook(t, server.URL, 1) // 1 second timeout
 
 	err := w.Send(context.Background(), "Test", `{"data":"test"}`)
 
@@ -232,3 +253,450 @@ func TestWebhook_SendWithUnreachableHost(t *testing.T) {
 
 	require.Error(t, err)
 }
+
+func TestWebhook_SendRetriesOnTransientFailure(t *testing.T) {
+	t.Parallel()
+
+	var attempts int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if atomic.AddInt32(&attempts, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	w := newAllowedWebhook(t, server.URL, 5).WithRetry(RetryConfig{
+		MaxAttempts: 3,
+		BaseBackoff: time.Millisecond,
+		MaxBackoff:  10 * time.Millisecond,
+	})
+
+	err := w.Send(context.Background(), "Test", `{"data":"test"}`)
+
+	require.NoError(t, err)
+	require.EqualValues(t, 3, atomic.LoadInt32(&attempts))
+}
+
+func TestWebhook_SendGivesUpAfterMaxAttempts(t *testing.T) {
+	t.Parallel()
+
+	var attempts int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	w := newAllowedWebhook(t, server.URL, 5).WithRetry(RetryConfig{
+		MaxAttempts: 3,
+		BaseBackoff: time.Millisecond,
+		MaxBackoff:  10 * time.Millisecond,
+	})
+
+	err := w.Send(context.Background(), "Test", `{"data":"test"}`)
+
+	require.Error(t, err)
+	require.EqualValues(t, 3, atomic.LoadInt32(&attempts))
+
+	var attemptErr *AttemptError
+	require.ErrorAs(t, err, &attemptErr)
+	require.Equal(t, 3, attemptErr.Attempts)
+	require.Equal(t, http.StatusInternalServerError, attemptErr.StatusCode)
+}
+
+func TestWebhook_SendDoesNotRetryNonTransientStatus(t *testing.T) {
+	t.Parallel()
+
+	var attempts int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	w := newAllowedWebhook(t, server.URL, 5).WithRetry(RetryConfig{
+		MaxAttempts: 3,
+		BaseBackoff: time.Millisecond,
+		MaxBackoff:  10 * time.Millisecond,
+	})
+
+	err := w.Send(context.Background(), "Test", `{"data":"test"}`)
+
+	require.Error(t, err)
+	require.EqualValues(t, 1, atomic.LoadInt32(&attempts))
+}
+
+func TestWebhook_SendHonorsRetryAfterSeconds(t *testing.T) {
+	t.Parallel()
+
+	var attempts int32
+	var firstAttempt time.Time
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			firstAttempt = time.Now()
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	w := newAllowedWebhook(t, server.URL, 5).WithRetry(RetryConfig{
+		MaxAttempts: 2,
+		BaseBackoff: time.Millisecond,
+		MaxBackoff:  10 * time.Millisecond,
+	})
+
+	err := w.Send(context.Background(), "Test", `{"data":"test"}`)
+
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, time.Since(firstAttempt), time.Second)
+}
+
+func TestNewWithOptions(t *testing.T) {
+	t.Parallel()
+
+	w := NewWithOptions("https://example.com/webhook", WithTimeoutSeconds(7))
+
+	require.NotNil(t, w)
+	require.Equal(t, "https://example.com/webhook", w.webhook)
+	require.Equal(t, 7, w.timeout)
+}
+
+func TestNew_IsEquivalentToNewWithOptionsTimeout(t *testing.T) {
+	t.Parallel()
+
+	legacy := New("https://example.com/webhook", 10)
+	withOpts := NewWithOptions("https://example.com/webhook", WithTimeoutSeconds(10))
+
+	require.Equal(t, legacy.webhook, withOpts.webhook)
+	require.Equal(t, legacy.timeout, withOpts.timeout)
+}
+
+func TestWebhook_SendSignsPayload(t *testing.T) {
+	t.Parallel()
+
+	const secret = "shhh"
+	const message = `{"data":"test"}`
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.Equal(t, message, string(body))
+
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(body)
+		expected := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+		require.Equal(t, expected, r.Header.Get(DefaultSignatureHeader))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	w := newAllowedWebhook(t, server.URL, 5).WithSigning(SignConfig{Secret: secret})
+
+	err := w.Send(context.Background(), "Test", message)
+
+	require.NoError(t, err)
+}
+
+func TestWebhook_SendSignsPayloadWithTimestamp(t *testing.T) {
+	t.Parallel()
+
+	const secret = "shhh"
+	const message = `{"data":"test"}`
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		ts := r.Header.Get("X-Webhook-Timestamp")
+		require.NotEmpty(t, ts)
+
+		mac := hmac.New(sha512.New, []byte(secret))
+		mac.Write([]byte(fmt.Sprintf("v0:%s:%s", ts, body)))
+		expected := "sha512=" + hex.EncodeToString(mac.Sum(nil))
+
+		require.Equal(t, expected, r.Header.Get(DefaultSignatureHeader))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	w := newAllowedWebhook(t, server.URL, 5).WithSigning(SignConfig{
+		Secret:          secret,
+		Algorithm:       SignSHA512,
+		TimestampHeader: "X-Webhook-Timestamp",
+	})
+
+	err := w.Send(context.Background(), "Test", message)
+
+	require.NoError(t, err)
+}
+
+func TestWebhook_SendRejectsLoopbackByDefault(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	// The host policy guard applies even without any WithAllowedHosts,
+	// WithDeniedCIDRs or WithProxy call, so the httptest server (bound to
+	// 127.0.0.1) must be rejected by the built-in loopback denylist alone.
+	w := New(server.URL, 2)
+
+	err := w.Send(context.Background(), "Test", `{"data":"test"}`)
+
+	require.Error(t, err)
+}
+
+func TestWebhook_SendAllowsExplicitlyAllowedHost(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	w := New(server.URL, 2).WithAllowedHosts([]string{u.Hostname()})
+
+	sendErr := w.Send(context.Background(), "Test", `{"data":"test"}`)
+
+	require.NoError(t, sendErr)
+}
+
+func TestWebhook_SendAllowedHostOverridesDeniedCIDR(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	w := New(server.URL, 2).
+		WithAllowedHosts([]string{u.Hostname()}).
+		WithDeniedCIDRs([]string{u.Hostname() + "/32"})
+
+	sendErr := w.Send(context.Background(), "Test", `{"data":"test"}`)
+
+	require.NoError(t, sendErr)
+}
+
+func TestWebhook_SendRejectsUnreachableMetadataAddress(t *testing.T) {
+	t.Parallel()
+
+	w := New("http://169.254.169.254/latest/meta-data", 1)
+
+	err := w.Send(context.Background(), "Test", `{"data":"test"}`)
+
+	require.Error(t, err)
+}
+
+func TestWebhook_SendFailsWithInvalidProxyURL(t *testing.T) {
+	t.Parallel()
+
+	w := New("http://192.0.2.1", 2).WithProxy("http://[::1]:badport")
+
+	err := w.Send(context.Background(), "Test", `{"data":"test"}`)
+
+	require.Error(t, err)
+}
+
+func TestWebhook_AsyncSendSurvivesCallerCancellation(t *testing.T) {
+	t.Parallel()
+
+	received := make(chan struct{})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.ReadAll(r.Body)
+		close(received)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	w := newAllowedWebhook(t, server.URL, 5)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := w.AsyncSend(ctx, "Test", `{"data":"test"}`)
+	cancel() // cancel immediately; delivery must still complete
+
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("AsyncSend did not complete after caller cancellation")
+	}
+
+	select {
+	case <-received:
+	default:
+		t.Fatal("server never received the request")
+	}
+}
+
+func TestWebhook_AsyncSendBoundedConcurrency(t *testing.T) {
+	t.Parallel()
+
+	var inFlight int32
+	var maxInFlight int32
+	release := make(chan struct{})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		for {
+			old := atomic.LoadInt32(&maxInFlight)
+			if n <= old || atomic.CompareAndSwapInt32(&maxInFlight, old, n) {
+				break
+			}
+		}
+		<-release
+		atomic.AddInt32(&inFlight, -1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	w := newAllowedWebhook(t, server.URL, 5).WithAsync(AsyncConfig{MaxConcurrent: 2})
+
+	// AsyncSend blocks its caller once the pool's queue is full rather than
+	// spawning a goroutine to wait (see WithAsync), so each call here needs
+	// its own goroutine, just as distinct callers would have in practice.
+	doneCh := make(chan (<-chan error), 5)
+	for i := 0; i < 5; i++ {
+		go func() {
+			doneCh <- w.AsyncSend(context.Background(), "Test", `{"data":"test"}`)
+		}()
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	require.LessOrEqual(t, atomic.LoadInt32(&maxInFlight), int32(2))
+
+	close(release)
+
+	for i := 0; i < 5; i++ {
+		done := <-doneCh
+		select {
+		case err := <-done:
+			require.NoError(t, err)
+		case <-time.After(2 * time.Second):
+			t.Fatal("AsyncSend did not complete")
+		}
+	}
+}
+
+// TestWebhook_AsyncSendDoesNotLeakGoroutinesWhenPoolSaturated guards against
+// the enqueue pattern AsyncSend used to have: a fresh goroutine per call
+// blocked sending to the pool's channel, which piled up unboundedly under
+// sustained load even though the pool itself was bounded. AsyncSend now
+// enqueues synchronously in the caller's own goroutine, so no such
+// goroutines should exist once every call has returned.
+func TestWebhook_AsyncSendDoesNotLeakGoroutinesWhenPoolSaturated(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	w := newAllowedWebhook(t, server.URL, 5).WithAsync(AsyncConfig{MaxConcurrent: 2})
+
+	before := runtime.NumGoroutine()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-w.AsyncSend(context.Background(), "Test", `{"data":"test"}`)
+		}()
+	}
+	wg.Wait()
+
+	require.Eventually(t, func() bool {
+		return runtime.NumGoroutine() <= before+5
+	}, 2*time.Second, 10*time.Millisecond, "goroutines leaked past the bounded pool")
+}
+
+// TestWebhook_AsyncSendCompletesWhenPoolReconfiguredWhileBlocked guards
+// against a race where AsyncSend blocks sending to a full pool queue, the
+// pool is reconfigured out from under it, and its old workers drain away
+// with nothing left to read that send: without a way to notice, AsyncSend
+// would block on that channel forever instead of falling back to its own
+// goroutine.
+func TestWebhook_AsyncSendCompletesWhenPoolReconfiguredWhileBlocked(t *testing.T) {
+	t.Parallel()
+
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		<-release
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	w := newAllowedWebhook(t, server.URL, 5).WithAsync(AsyncConfig{MaxConcurrent: 1})
+
+	// The first call keeps the sole worker busy; the second fills its
+	// one-deep queue. The third has nowhere to go and blocks sending.
+	_ = w.AsyncSend(context.Background(), "Test", `{"data":"test"}`)
+	_ = w.AsyncSend(context.Background(), "Test", `{"data":"test"}`)
+
+	blocked := make(chan (<-chan error), 1)
+	go func() {
+		blocked <- w.AsyncSend(context.Background(), "Test", `{"data":"test"}`)
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+
+	// Reconfiguring now stops the old pool's workers while the third call
+	// is still blocked trying to hand its job to them.
+	w.WithAsync(AsyncConfig{MaxConcurrent: 1})
+
+	close(release)
+
+	third := <-blocked
+
+	select {
+	case err := <-third:
+		require.NoError(t, err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("AsyncSend stayed blocked on a reconfigured pool")
+	}
+}
+
+func TestWebhook_SendAbortsOnContextCancellationDuringBackoff(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	w := newAllowedWebhook(t, server.URL, 5).WithRetry(RetryConfig{
+		MaxAttempts: 5,
+		BaseBackoff: time.Second,
+		MaxBackoff:  time.Second,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := w.Send(ctx, "Test", `{"data":"test"}`)
+	elapsed := time.Since(start)
+
+	require.Error(t, err)
+	require.Less(t, elapsed, time.Second)
+}